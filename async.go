@@ -0,0 +1,105 @@
+package batcher
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+// ErrClosed is returned by Send and SendAsync once the batcher has been
+// closed, instead of accepting the operation.
+var ErrClosed = errors.New("batcher: closed")
+
+// SendAsync creates a new operation and sends it to the batcher without
+// blocking, returning immediately. Unlike Send, it takes no context: the
+// operation is queued in the background and delivered to Start whenever it
+// is ready to receive it. Use [Operation.Wait] on the returned operation to
+// observe its result.
+//
+// If the batcher has been closed, or max byte size is set and v is larger
+// than it on its own, the returned operation is already completed with
+// [ErrClosed] or [ErrOperationTooLarge] respectively.
+func (b *Batcher[T, R]) SendAsync(v T) *Operation[T, R] {
+	op := newOperation[T, R](v)
+
+	if b.maxBytes != UnlimitedBytes && b.sizeOf(v) > b.maxBytes {
+		atomic.AddUint64(&b.oversizeRejected, 1)
+		var zero R
+		op.Complete(zero, ErrOperationTooLarge)
+		return op
+	}
+
+	// closed is checked and asyncWG.Add is called under b.mu, so this can
+	// never race with Close's store-then-Wait: either this runs first and
+	// Close's Wait is guaranteed to see the Add, or Close's store runs first
+	// and this bails out before ever touching asyncWG.
+	b.mu.Lock()
+	if atomic.LoadInt32(&b.closed) == 1 {
+		b.mu.Unlock()
+		var zero R
+		op.Complete(zero, ErrClosed)
+		return op
+	}
+	b.asyncWG.Add(1)
+	b.mu.Unlock()
+
+	go func() {
+		defer b.asyncWG.Done()
+		select {
+		case b.in <- op:
+		case <-b.stopc:
+			var zero R
+			op.Complete(zero, ErrClosed)
+		}
+	}()
+
+	return op
+}
+
+// Close stops the batcher from accepting new operations, causes Start to
+// commit its final, possibly partial, batch, and waits for Start to return
+// (including, if workers are configured, for in-flight commits to drain).
+// This lets the batcher be used from a short-lived process that cannot keep
+// a long-lived context and goroutine around just to call Start: callers run
+// Start in the background with a long-lived context, such as
+// [context.Background], and call Close when done producing operations.
+//
+// If ctx expires before Start has finished, Close returns ctx.Err(). Close may
+// be called before Start has begun running, and calling it multiple times
+// (including after a prior call timed out) is safe and waits the same way
+// each time; it always retries cancelling Start until Start has returned.
+func (b *Batcher[T, R]) Close(ctx context.Context) error {
+	b.mu.Lock()
+	atomic.StoreInt32(&b.closed, 1)
+	b.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		b.asyncWG.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case <-b.started:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	b.mu.Lock()
+	cancel := b.cancel
+	b.mu.Unlock()
+	cancel()
+
+	select {
+	case <-b.stopc:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
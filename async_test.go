@@ -0,0 +1,107 @@
+package batcher
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBatcherSendAsyncAndClose(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		received []int
+	)
+
+	b := New[int, any](
+		func(_ context.Context, ops []*Operation[int, any]) {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, op := range ops {
+				received = append(received, op.Value)
+				op.Complete(nil, nil)
+			}
+		},
+		WithTimeout[int, any](time.Second),
+	)
+
+	go b.Start(context.Background())
+
+	var ops []*Operation[int, any]
+	for i := 0; i < 3; i++ {
+		ops = append(ops, b.SendAsync(i))
+	}
+
+	closeCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := b.Close(closeCtx); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	for _, op := range ops {
+		if _, err := op.Wait(context.Background()); err != nil {
+			t.Errorf("Wait: %v", err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 3 {
+		t.Fatalf("got %d operations committed, want 3", len(received))
+	}
+}
+
+func TestBatcherSendAsyncConcurrentWithClose(t *testing.T) {
+	b := New[int, any](
+		func(_ context.Context, ops []*Operation[int, any]) {
+			for _, op := range ops {
+				op.Complete(nil, nil)
+			}
+		},
+		WithTimeout[int, any](time.Second),
+	)
+
+	go b.Start(context.Background())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(v int) {
+			defer wg.Done()
+			op := b.SendAsync(v)
+			op.Wait(context.Background())
+		}(i)
+	}
+
+	closeCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := b.Close(closeCtx); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	wg.Wait()
+}
+
+func TestBatcherSendAsyncAfterCloseReturnsErrClosed(t *testing.T) {
+	b := New[int, any](
+		func(context.Context, []*Operation[int, any]) {},
+		WithTimeout[int, any](time.Second),
+	)
+
+	go b.Start(context.Background())
+
+	closeCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := b.Close(closeCtx); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	op := b.SendAsync(1)
+	if _, err := op.Wait(context.Background()); err != ErrClosed {
+		t.Fatalf("Wait error = %v, want ErrClosed", err)
+	}
+
+	if _, err := b.Send(context.Background(), 1); err != ErrClosed {
+		t.Fatalf("Send error = %v, want ErrClosed", err)
+	}
+}
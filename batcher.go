@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"sync"
 	"sync/atomic"
 	"time"
 )
@@ -19,20 +20,44 @@ const NoTimeout time.Duration = 0
 type Batcher[T, R any] struct {
 	commitFn          CommitFunc[T, R]
 	maxSize           int
+	maxBytes          int
+	sizeFunc          func(T) int
+	minSize           int
 	timeout           time.Duration
+	maxLatency        time.Duration
+	workers           int
+	splitter          func([]*Operation[T, R]) [][]*Operation[T, R]
 	in                chan *Operation[T, R]
 	registry          prometheus.Registerer
 	namespace         string
 	subsystem         string
-	batchSizeReached  uint64
-	batchTimerReached uint64
+	constLabels       prometheus.Labels
+	histogramBuckets  []float64
+	oversizeRejected  uint64
+	batchesInFlight   int64
+	batchFlushReason  *prometheus.CounterVec
+	batchSize         prometheus.Histogram
+	batchBytesHist    prometheus.Histogram
+	batchFillDuration prometheus.Histogram
+	commitDuration    prometheus.Histogram
+	mu                sync.Mutex
+	cancel            context.CancelFunc
+	started           chan struct{}
+	stopc             chan struct{}
+	closed            int32
+	asyncWG           sync.WaitGroup
+	queue             Queue[T]
+	retryFn           RetryableCommitFunc[T, R]
+	retryPolicy       RetryPolicy
 }
 
 // New creates a new batcher, calling the commit function each time it
 // completes a batch of operations according to its options. It panics if the
-// commit function is nil, max size is negative, timeout is negative or max
-// size equals [UnlimitedSize] and timeout equals [NoTimeout] (the default if
-// no options are provided).
+// commit function is nil, max size or max byte size is negative, timeout is
+// negative, max size, max byte size and timeout all equal their defaults
+// ([UnlimitedSize], [UnlimitedBytes] and [NoTimeout] respectively, the
+// default if no options are provided), or [WithQueue] is set (use
+// [NewDurable] instead).
 //
 // Some examples:
 //
@@ -50,58 +75,206 @@ type Batcher[T, R any] struct {
 //
 //	New[T, R](commitFn, WithMaxSize(10), WithTimeout(1 * time.Second))
 func New[T, R any](commitFn CommitFunc[T, R], opts ...Option[T, R]) *Batcher[T, R] {
-	b := &Batcher[T, R]{
-		commitFn: commitFn,
-		maxSize:  UnlimitedSize,
-		timeout:  NoTimeout,
-		in:       make(chan *Operation[T, R]),
+	b := newBatcher[T, R](opts...)
+	b.commitFn = commitFn
+
+	if b.commitFn == nil {
+		panic("batcher: nil commit func")
 	}
 
-	for _, opt := range opts {
-		opt(b)
+	if b.queue != nil {
+		panic("batcher: queue set, use NewDurable")
 	}
 
-	if b.commitFn == nil {
+	b.registerMetrics()
+	return b
+}
+
+// NewDurable creates a new batcher backed by a durable [Queue] (set via
+// [WithQueue], which is required), calling the commit function for each
+// batch it completes and only acking a batch's entries once the commit
+// function returns nil. If it returns an error, Start retries the batch,
+// backing off according to [WithRetryPolicy], instead of acking it — so the
+// batch is redelivered by the queue after a restart if the process dies
+// before a retry succeeds. This turns the batcher into a durable outbox, at
+// the cost of the batching behaviors split, min size and workers provide,
+// which NewDurable does not support.
+//
+// NewDurable panics under the same option conditions as [New] except the one
+// on [WithQueue], plus if commitFn or the queue is nil, or if [WithWorkers],
+// [WithSplitter] or [WithMinSize] is set.
+func NewDurable[T, R any](commitFn RetryableCommitFunc[T, R], opts ...Option[T, R]) *Batcher[T, R] {
+	b := newBatcher[T, R](opts...)
+	b.retryFn = commitFn
+
+	if b.retryFn == nil {
 		panic("batcher: nil commit func")
 	}
 
+	if b.queue == nil {
+		panic("batcher: nil queue, use WithQueue")
+	}
+
+	if b.workers > 0 || b.splitter != nil || b.minSize > 0 {
+		panic("batcher: workers, splitter and min size are not supported by NewDurable")
+	}
+
+	b.registerMetrics()
+	return b
+}
+
+func newBatcher[T, R any](opts ...Option[T, R]) *Batcher[T, R] {
+	b := &Batcher[T, R]{
+		maxSize: UnlimitedSize,
+		timeout: NoTimeout,
+		in:      make(chan *Operation[T, R]),
+		started: make(chan struct{}),
+		stopc:   make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
 	if b.maxSize < 0 {
 		panic("batcher: negative max size")
 	}
 
+	if b.maxBytes < 0 {
+		panic("batcher: negative max byte size")
+	}
+
 	if b.timeout < 0 {
 		panic("batcher: negative timeout")
 	}
 
-	if b.maxSize == UnlimitedSize && b.timeout == NoTimeout {
+	if b.maxSize == UnlimitedSize && b.maxBytes == UnlimitedBytes && b.timeout == NoTimeout {
 		panic("batcher: unlimited size with no timeout")
 	}
 
+	if b.minSize < 0 {
+		panic("batcher: negative min size")
+	}
+
+	if b.maxLatency < 0 {
+		panic("batcher: negative max latency")
+	}
+
+	if b.minSize > 0 && b.timeout == NoTimeout {
+		panic("batcher: min size with no timeout")
+	}
+
+	if b.minSize > 0 && b.maxLatency == NoTimeout {
+		panic("batcher: min size with no max latency")
+	}
+
+	if b.workers < 0 {
+		panic("batcher: negative workers")
+	}
+
+	return b
+}
+
+// registerMetrics registers the batcher's Prometheus metrics. It is called
+// once by New and NewDurable, after the commit function has been validated,
+// so a batcher that panics during construction never registers metrics.
+func (b *Batcher[T, R]) registerMetrics() {
+	b.batchFlushReason = promauto.With(b.registry).NewCounterVec(
+		prometheus.CounterOpts{
+			Name:        prometheus.BuildFQName(b.namespace, b.subsystem, "batch_flush_reason_total"),
+			Help:        "Number of batches committed, by the reason they were flushed (size, bytes, timer, shutdown), plus min_wait counting times an under-filled batch was held back for max latency instead.",
+			ConstLabels: b.constLabels,
+		},
+		[]string{"reason"})
+
 	promauto.With(b.registry).NewCounterFunc(
 		prometheus.CounterOpts{
-			Name: prometheus.BuildFQName(b.namespace, b.subsystem, "batch_size_reached_total"),
-			Help: "Number of batchs that reached batch size.",
+			Name:        prometheus.BuildFQName(b.namespace, b.subsystem, "oversize_rejected_total"),
+			Help:        "Number of operations rejected for exceeding max batch byte size.",
+			ConstLabels: b.constLabels,
 		},
 		func() float64 {
-			return float64(atomic.LoadUint64(&b.batchSizeReached))
+			return float64(atomic.LoadUint64(&b.oversizeRejected))
 		})
 
-	promauto.With(b.registry).NewCounterFunc(
-		prometheus.CounterOpts{
-			Name: prometheus.BuildFQName(b.namespace, b.subsystem, "batch_timer_reached_total"),
-			Help: "Number of batchs that reached timer limit.",
+	promauto.With(b.registry).NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Name:        prometheus.BuildFQName(b.namespace, b.subsystem, "batches_in_flight"),
+			Help:        "Number of completed batches handed off to a worker but not yet committed.",
+			ConstLabels: b.constLabels,
 		},
 		func() float64 {
-			return float64(atomic.LoadUint64(&b.batchTimerReached))
+			return float64(atomic.LoadInt64(&b.batchesInFlight))
 		})
 
-	return b
+	b.batchSize = promauto.With(b.registry).NewHistogram(
+		prometheus.HistogramOpts{
+			Name:        prometheus.BuildFQName(b.namespace, b.subsystem, "batch_size"),
+			Help:        "Number of operations per committed batch.",
+			ConstLabels: b.constLabels,
+			Buckets:     b.buckets(),
+		})
+
+	if b.maxBytes != UnlimitedBytes {
+		b.batchBytesHist = promauto.With(b.registry).NewHistogram(
+			prometheus.HistogramOpts{
+				Name:        prometheus.BuildFQName(b.namespace, b.subsystem, "batch_bytes"),
+				Help:        "Accumulated byte size per committed batch.",
+				ConstLabels: b.constLabels,
+				Buckets:     b.buckets(),
+			})
+	}
+
+	b.batchFillDuration = promauto.With(b.registry).NewHistogram(
+		prometheus.HistogramOpts{
+			Name:        prometheus.BuildFQName(b.namespace, b.subsystem, "batch_fill_duration_seconds"),
+			Help:        "Time from a batch's first operation to it being committed.",
+			ConstLabels: b.constLabels,
+			Buckets:     b.buckets(),
+		})
+
+	b.commitDuration = promauto.With(b.registry).NewHistogram(
+		prometheus.HistogramOpts{
+			Name:        prometheus.BuildFQName(b.namespace, b.subsystem, "commit_duration_seconds"),
+			Help:        "Time taken by each call to the commit function.",
+			ConstLabels: b.constLabels,
+			Buckets:     b.buckets(),
+		})
+}
+
+// buckets returns the histogram bucket boundaries set by
+// [WithHistogramBuckets], or the client's default buckets if unset.
+func (b *Batcher[T, R]) buckets() []float64 {
+	if b.histogramBuckets != nil {
+		return b.histogramBuckets
+	}
+	return prometheus.DefBuckets
+}
+
+// commitAndTime calls the commit function on batch, observing how long it
+// took in the commit_duration_seconds histogram.
+func (b *Batcher[T, R]) commitAndTime(ctx context.Context, batch []*Operation[T, R]) {
+	start := time.Now()
+	b.commitFn(ctx, batch)
+	b.commitDuration.Observe(time.Since(start).Seconds())
 }
 
 // Send creates a new operation and sends it to the batcher in a blocking
 // fashion. If the provided context expires before the batcher receives the
-// operation, Send returns the context's error.
+// operation, Send returns the context's error. If max byte size is set and v
+// is larger than it on its own, Send rejects it with [ErrOperationTooLarge]
+// instead of sending it. Send returns [ErrClosed] once [Batcher.Close] has
+// been called.
 func (b *Batcher[T, R]) Send(ctx context.Context, v T) (*Operation[T, R], error) {
+	if atomic.LoadInt32(&b.closed) == 1 {
+		return nil, ErrClosed
+	}
+
+	if b.maxBytes != UnlimitedBytes && b.sizeOf(v) > b.maxBytes {
+		atomic.AddUint64(&b.oversizeRejected, 1)
+		return nil, ErrOperationTooLarge
+	}
+
 	op := newOperation[T, R](v)
 	select {
 	case b.in <- op:
@@ -113,46 +286,143 @@ func (b *Batcher[T, R]) Send(ctx context.Context, v T) (*Operation[T, R], error)
 
 // Start receives operations from the batcher, calling the commit function
 // whenever max size is reached or a timeout occurs. Timeouts are disabled
-// while receiving the first operation of each batch.
+// while receiving the first operation of each batch. If min size is set, a
+// timeout is not enough to flush an under-filled batch on its own; it is
+// held back until max latency has also elapsed since the batch's first
+// operation.
 //
-// When the provided context expires, the batching process is interrupted and
-// the function returns after a final call to the commit function. The latter
-// is skipped if there are no latent operations.
+// If workers is set, completed batches are handed off to a pool of worker
+// goroutines that call the commit function concurrently, so a slow commit no
+// longer blocks intake; otherwise the commit function is called inline. If a
+// splitter is set, it is applied to the completed batch first, and the
+// commit function (or a worker) is called once per returned sub-batch.
+//
+// When the provided context expires, or [Batcher.Close] is called, the
+// batching process is interrupted and the function returns after a final
+// call to the commit function. The latter is skipped if there are no latent
+// operations. If workers is set, Start additionally waits for all in-flight
+// commits to finish before returning.
 func (b *Batcher[T, R]) Start(ctx context.Context) {
+	if b.queue != nil {
+		b.startDurable(ctx)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	b.mu.Lock()
+	b.cancel = cancel
+	b.mu.Unlock()
+	close(b.started)
+	defer cancel()
+	defer close(b.stopc)
+
 	var out []*Operation[T, R]
 	if b.maxSize != UnlimitedSize {
 		out = make([]*Operation[T, R], 0, b.maxSize)
 	}
+	var bytes int
+	var batchStart time.Time
 
 	var (
 		t *time.Timer
 		c <-chan time.Time
 	)
 
+	var commitCh chan []*Operation[T, R]
+	var workers sync.WaitGroup
+	if b.workers > 0 {
+		commitCh = make(chan []*Operation[T, R], b.workers)
+		for i := 0; i < b.workers; i++ {
+			workers.Add(1)
+			go func() {
+				defer workers.Done()
+				for batch := range commitCh {
+					b.commitAndTime(ctx, batch)
+					atomic.AddInt64(&b.batchesInFlight, -1)
+				}
+			}()
+		}
+	}
+
 	for {
 		var commit, done bool
+		var reason string
+		var pending *Operation[T, R]
 		select {
 		case op := <-b.in:
-			out = append(out, op)
-			if len(out) == b.maxSize {
+			size := b.sizeOf(op.Value)
+			if len(out) > 0 && b.maxBytes != UnlimitedBytes && bytes+size > b.maxBytes {
 				commit = true
-				atomic.AddUint64(&b.batchSizeReached, 1)
+				reason = "bytes"
+				pending = op
+			} else {
+				if len(out) == 0 {
+					batchStart = time.Now()
+				}
+				out = append(out, op)
+				bytes += size
+				if len(out) == b.maxSize {
+					commit = true
+					reason = "size"
+				} else if b.maxBytes != UnlimitedBytes && bytes >= b.maxBytes {
+					commit = true
+					reason = "bytes"
+				}
 			}
 		case <-c:
-			commit = true
-			atomic.AddUint64(&b.batchTimerReached, 1)
+			if b.minSize > 0 && len(out) < b.minSize && time.Since(batchStart) < b.maxLatency {
+				b.batchFlushReason.WithLabelValues("min_wait").Inc()
+				c = nil
+			} else {
+				commit = true
+				reason = "timer"
+			}
 		case <-ctx.Done():
 			if len(out) > 0 {
 				commit = true
+				reason = "shutdown"
 			}
 			done = true
 		}
 
 		if commit {
-			b.commitFn(ctx, out)
+			b.batchFlushReason.WithLabelValues(reason).Inc()
+			b.batchFillDuration.Observe(time.Since(batchStart).Seconds())
+
+			for _, batch := range splitBatch(b.splitter, out) {
+				b.batchSize.Observe(float64(len(batch)))
+				if b.batchBytesHist != nil {
+					b.batchBytesHist.Observe(float64(b.batchBytes(batch)))
+				}
+
+				if commitCh != nil {
+					atomic.AddInt64(&b.batchesInFlight, 1)
+					commitCh <- batch
+				} else {
+					b.commitAndTime(ctx, batch)
+				}
+			}
+
+			if commitCh != nil {
+				if b.maxSize != UnlimitedSize {
+					out = make([]*Operation[T, R], 0, b.maxSize)
+				} else {
+					out = nil
+				}
+			} else {
+				out = out[:0]
+			}
 
 			c = nil
-			out = out[:0]
+			bytes = 0
+		}
+
+		if pending != nil {
+			batchStart = time.Now()
+			out = append(out, pending)
+			bytes += b.sizeOf(pending.Value)
+			// pending starts a new batch, so arm the timer for it below.
+			commit = false
 		}
 
 		if done {
@@ -168,4 +438,9 @@ func (b *Batcher[T, R]) Start(ctx context.Context) {
 			c = t.C
 		}
 	}
+
+	if commitCh != nil {
+		close(commitCh)
+		workers.Wait()
+	}
 }
@@ -0,0 +1,133 @@
+package batcher
+
+import (
+	"context"
+	"time"
+)
+
+// Enqueue adds v to the durable queue backing a batcher created with
+// [NewDurable], blocking until there's room or ctx expires. Unlike Send, it
+// returns no operation to wait on: v may not be committed until after a
+// process restart, by which time a caller waiting on it would be gone.
+func (b *Batcher[T, R]) Enqueue(ctx context.Context, v T) error {
+	return b.queue.Enqueue(ctx, v)
+}
+
+// startDurable is Start's loop for a batcher created with [NewDurable]. It
+// mirrors Start's size/timeout batching, but sources operations from the
+// queue instead of Send, and acks them only once commitWithRetry succeeds.
+func (b *Batcher[T, R]) startDurable(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	b.mu.Lock()
+	b.cancel = cancel
+	b.mu.Unlock()
+	close(b.started)
+	defer cancel()
+	defer close(b.stopc)
+
+	deqc := make(chan queueEntry[T])
+	go func() {
+		for {
+			v, id, err := b.queue.Dequeue(ctx)
+			if err != nil {
+				return
+			}
+			select {
+			case deqc <- queueEntry[T]{ID: id, V: v}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var out []*Operation[T, R]
+	var ids []uint64
+	if b.maxSize != UnlimitedSize {
+		out = make([]*Operation[T, R], 0, b.maxSize)
+		ids = make([]uint64, 0, b.maxSize)
+	}
+
+	var (
+		t          *time.Timer
+		c          <-chan time.Time
+		batchStart time.Time
+	)
+
+	for {
+		var commit, done bool
+		var reason string
+		select {
+		case e := <-deqc:
+			if len(out) == 0 {
+				batchStart = time.Now()
+			}
+			out = append(out, newOperation[T, R](e.V))
+			ids = append(ids, e.ID)
+			if len(out) == b.maxSize {
+				commit = true
+				reason = "size"
+			}
+		case <-c:
+			commit = true
+			reason = "timer"
+		case <-ctx.Done():
+			if len(out) > 0 {
+				commit = true
+				reason = "shutdown"
+			}
+			done = true
+		}
+
+		if commit {
+			b.batchFlushReason.WithLabelValues(reason).Inc()
+			b.batchSize.Observe(float64(len(out)))
+			b.batchFillDuration.Observe(time.Since(batchStart).Seconds())
+
+			b.commitWithRetry(ctx, out, ids)
+
+			c = nil
+			out = out[:0]
+			ids = ids[:0]
+		}
+
+		if done {
+			break
+		}
+
+		if !commit && c == nil && b.timeout != NoTimeout {
+			if t == nil {
+				t = time.NewTimer(b.timeout)
+			} else {
+				t.Reset(b.timeout)
+			}
+			c = t.C
+		}
+	}
+}
+
+// commitWithRetry calls the durable commit function, retrying with backoff
+// until it succeeds or ctx expires, then acks every entry in the batch.
+func (b *Batcher[T, R]) commitWithRetry(ctx context.Context, out []*Operation[T, R], ids []uint64) {
+	for attempt := 0; ; attempt++ {
+		start := time.Now()
+		err := b.retryFn(ctx, out)
+		b.commitDuration.Observe(time.Since(start).Seconds())
+		if err == nil {
+			break
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		select {
+		case <-time.After(b.retryPolicy.backoff(attempt)):
+		case <-ctx.Done():
+			return
+		}
+	}
+
+	for _, id := range ids {
+		b.queue.Ack(id)
+	}
+}
@@ -0,0 +1,202 @@
+package batcher
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNewPanicsOnQueueSet(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic")
+		}
+	}()
+
+	New[int, any](
+		func(context.Context, []*Operation[int, any]) {},
+		WithMaxSize[int, any](1),
+		WithQueue[int, any](NewMemQueue[int](10)),
+	)
+}
+
+func TestNewDurablePanicsOnUnsupportedOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option[int, any]
+	}{
+		{"workers", []Option[int, any]{WithWorkers[int, any](5)}},
+		{"splitter", []Option[int, any]{WithSplitter[int, any](func(ops []*Operation[int, any]) [][]*Operation[int, any] {
+			return [][]*Operation[int, any]{ops}
+		})}},
+		{"min size", []Option[int, any]{
+			WithTimeout[int, any](10 * time.Millisecond),
+			WithMaxLatency[int, any](10 * time.Millisecond),
+			WithMinSize[int, any](5),
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("expected panic")
+				}
+			}()
+
+			opts := append([]Option[int, any]{WithQueue[int, any](NewMemQueue[int](10))}, tt.opts...)
+			NewDurable[int, any](
+				func(context.Context, []*Operation[int, any]) error { return nil },
+				opts...,
+			)
+		})
+	}
+}
+
+// ackCountingQueue wraps a Queue, counting calls to Ack.
+type ackCountingQueue struct {
+	Queue[int]
+	acks int64
+}
+
+func (q *ackCountingQueue) Ack(id uint64) error {
+	atomic.AddInt64(&q.acks, 1)
+	return q.Queue.Ack(id)
+}
+
+func TestBatcherDurableCommitsAndAcks(t *testing.T) {
+	q := &ackCountingQueue{Queue: NewMemQueue[int](10)}
+
+	var (
+		mu      sync.Mutex
+		batches [][]int
+	)
+
+	b := NewDurable[int, any](
+		func(_ context.Context, ops []*Operation[int, any]) error {
+			mu.Lock()
+			defer mu.Unlock()
+			var vals []int
+			for _, op := range ops {
+				vals = append(vals, op.Value)
+			}
+			batches = append(batches, vals)
+			return nil
+		},
+		WithMaxSize[int, any](2),
+		WithQueue[int, any](q),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go b.Start(ctx)
+
+	for _, v := range []int{1, 2} {
+		if err := b.Enqueue(context.Background(), v); err != nil {
+			t.Fatalf("Enqueue(%d): %v", v, err)
+		}
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(batches) != 1 || len(batches[0]) != 2 {
+		t.Fatalf("got batches %v, want one batch of 2", batches)
+	}
+	if q.Len() != 0 {
+		t.Errorf("queue len = %d, want 0 once dequeued", q.Len())
+	}
+	if got := atomic.LoadInt64(&q.acks); got != 2 {
+		t.Errorf("acks = %d, want 2", got)
+	}
+}
+
+func TestBatcherDurableRetriesUntilSuccess(t *testing.T) {
+	q := NewMemQueue[int](10)
+
+	var attempts int64
+
+	b := NewDurable[int, any](
+		func(_ context.Context, ops []*Operation[int, any]) error {
+			if atomic.AddInt64(&attempts, 1) < 3 {
+				return errors.New("commit failed")
+			}
+			return nil
+		},
+		WithMaxSize[int, any](1),
+		WithQueue[int, any](q),
+		WithRetryPolicy[int, any](RetryPolicy{InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go b.Start(ctx)
+
+	if err := b.Enqueue(context.Background(), 1); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	if got := atomic.LoadInt64(&attempts); got != 3 {
+		t.Errorf("attempts = %d, want 3", got)
+	}
+}
+
+func TestFileQueueReplaysUnackedEntries(t *testing.T) {
+	dir := t.TempDir()
+
+	q, err := NewFileQueue[string](dir)
+	if err != nil {
+		t.Fatalf("NewFileQueue: %v", err)
+	}
+
+	if err := q.Enqueue(context.Background(), "kept"); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	if err := q.Enqueue(context.Background(), "acked"); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	_, id, err := q.Dequeue(context.Background())
+	if err != nil {
+		t.Fatalf("Dequeue: %v", err)
+	}
+	_ = id
+
+	_, id2, err := q.Dequeue(context.Background())
+	if err != nil {
+		t.Fatalf("Dequeue: %v", err)
+	}
+	if err := q.Ack(id2); err != nil {
+		t.Fatalf("Ack: %v", err)
+	}
+
+	if err := q.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	reopened, err := NewFileQueue[string](dir)
+	if err != nil {
+		t.Fatalf("NewFileQueue (reopen): %v", err)
+	}
+	defer reopened.Close()
+
+	if reopened.Len() != 1 {
+		t.Fatalf("replayed len = %d, want 1", reopened.Len())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	v, _, err := reopened.Dequeue(ctx)
+	if err != nil {
+		t.Fatalf("Dequeue after reopen: %v", err)
+	}
+	if v != "kept" {
+		t.Errorf("replayed value = %q, want %q", v, "kept")
+	}
+}
@@ -0,0 +1,164 @@
+package batcher
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileQueue is a durable, on-disk [Queue] backed by an append-only log of
+// gob-encoded entries plus a log of acknowledged ids. On construction it
+// replays every logged entry that was never acked, so operations enqueued
+// but not committed before a crash or restart are not lost. T must be
+// gob-encodable.
+//
+// FileQueue is safe for concurrent use.
+type FileQueue[T any] struct {
+	mu      sync.Mutex
+	entries *os.File
+	enc     *gob.Encoder
+	acked   *os.File
+	ackEnc  *gob.Encoder
+	items   []queueEntry[T]
+	notify  chan struct{}
+	nextID  uint64
+}
+
+// NewFileQueue opens a durable queue rooted at dir, creating it if it
+// doesn't already exist.
+func NewFileQueue[T any](dir string) (*FileQueue[T], error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("batcher: create queue dir: %w", err)
+	}
+
+	acked, err := os.OpenFile(filepath.Join(dir, "acked.log"), os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("batcher: open ack log: %w", err)
+	}
+
+	ackedIDs := map[uint64]struct{}{}
+	ackDec := gob.NewDecoder(acked)
+	for {
+		var id uint64
+		if err := ackDec.Decode(&id); err != nil {
+			break
+		}
+		ackedIDs[id] = struct{}{}
+	}
+	if _, err := acked.Seek(0, io.SeekEnd); err != nil {
+		return nil, fmt.Errorf("batcher: seek ack log: %w", err)
+	}
+
+	entries, err := os.OpenFile(filepath.Join(dir, "entries.log"), os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("batcher: open entry log: %w", err)
+	}
+
+	q := &FileQueue[T]{
+		entries: entries,
+		enc:     gob.NewEncoder(entries),
+		acked:   acked,
+		ackEnc:  gob.NewEncoder(acked),
+		notify:  make(chan struct{}, 1),
+	}
+
+	dec := gob.NewDecoder(entries)
+	for {
+		var e queueEntry[T]
+		if err := dec.Decode(&e); err != nil {
+			break
+		}
+		if e.ID > q.nextID {
+			q.nextID = e.ID
+		}
+		if _, ok := ackedIDs[e.ID]; !ok {
+			q.items = append(q.items, e)
+		}
+	}
+	if _, err := entries.Seek(0, io.SeekEnd); err != nil {
+		return nil, fmt.Errorf("batcher: seek entry log: %w", err)
+	}
+
+	return q, nil
+}
+
+func (q *FileQueue[T]) signal() {
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (q *FileQueue[T]) Enqueue(ctx context.Context, v T) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	q.nextID++
+	e := queueEntry[T]{ID: q.nextID, V: v}
+	if err := q.enc.Encode(e); err != nil {
+		q.nextID--
+		return fmt.Errorf("batcher: append queue entry: %w", err)
+	}
+
+	q.items = append(q.items, e)
+	q.signal()
+	return nil
+}
+
+func (q *FileQueue[T]) Dequeue(ctx context.Context) (T, uint64, error) {
+	for {
+		q.mu.Lock()
+		if len(q.items) > 0 {
+			e := q.items[0]
+			q.items = q.items[1:]
+			q.mu.Unlock()
+			return e.V, e.ID, nil
+		}
+		q.mu.Unlock()
+
+		select {
+		case <-q.notify:
+		case <-ctx.Done():
+			var zero T
+			return zero, 0, ctx.Err()
+		}
+	}
+}
+
+func (q *FileQueue[T]) Ack(id uint64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := q.ackEnc.Encode(id); err != nil {
+		return fmt.Errorf("batcher: append ack: %w", err)
+	}
+	return nil
+}
+
+func (q *FileQueue[T]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items)
+}
+
+// Close closes the queue's underlying files. It does not ack or otherwise
+// discard unacked entries, which are replayed the next time NewFileQueue
+// opens the same dir.
+func (q *FileQueue[T]) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	err := q.entries.Close()
+	if ackErr := q.acked.Close(); err == nil {
+		err = ackErr
+	}
+	return err
+}
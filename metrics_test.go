@@ -0,0 +1,150 @@
+package batcher
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// histogramSampleCount returns the number of observations recorded by h.
+func histogramSampleCount(t *testing.T, h prometheus.Histogram) uint64 {
+	t.Helper()
+	var m dto.Metric
+	if err := h.Write(&m); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestBatcherMetricsObserveFlushesAndDurations(t *testing.T) {
+	reg := prometheus.NewRegistry()
+
+	b := New[int, any](
+		func(context.Context, []*Operation[int, any]) {},
+		WithMaxSize[int, any](2),
+		WithRegisterer[int, any](reg),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go b.Start(ctx)
+
+	for _, v := range []int{1, 2} {
+		if _, err := b.Send(context.Background(), v); err != nil {
+			t.Fatalf("Send(%d): %v", v, err)
+		}
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	time.Sleep(50 * time.Millisecond)
+
+	if got := testutil.ToFloat64(b.batchFlushReason.WithLabelValues("size")); got != 1 {
+		t.Errorf("batch_flush_reason_total{reason=size} = %v, want 1", got)
+	}
+	if got := histogramSampleCount(t, b.batchSize); got != 1 {
+		t.Errorf("batch_size observation count = %d, want 1", got)
+	}
+	if got := histogramSampleCount(t, b.batchFillDuration); got != 1 {
+		t.Errorf("batch_fill_duration_seconds observation count = %d, want 1", got)
+	}
+	if got := histogramSampleCount(t, b.commitDuration); got != 1 {
+		t.Errorf("commit_duration_seconds observation count = %d, want 1", got)
+	}
+}
+
+func TestBatcherWithMaxBytesRegistersBatchBytesHistogram(t *testing.T) {
+	reg := prometheus.NewRegistry()
+
+	b := New[sizedValue, any](
+		func(context.Context, []*Operation[sizedValue, any]) {},
+		WithMaxBytes[sizedValue, any](10),
+		WithRegisterer[sizedValue, any](reg),
+	)
+
+	if b.batchBytesHist == nil {
+		t.Fatal("batchBytesHist is nil, want a registered histogram when max bytes is set")
+	}
+}
+
+func TestBatcherWithoutMaxBytesOmitsBatchBytesHistogram(t *testing.T) {
+	reg := prometheus.NewRegistry()
+
+	b := New[int, any](
+		func(context.Context, []*Operation[int, any]) {},
+		WithMaxSize[int, any](1),
+		WithRegisterer[int, any](reg),
+	)
+
+	if b.batchBytesHist != nil {
+		t.Fatal("batchBytesHist is non-nil, want nil when max bytes is unset")
+	}
+}
+
+func TestBatcherMetricsObserveEachSplitSubBatch(t *testing.T) {
+	reg := prometheus.NewRegistry()
+
+	b := New[int, any](
+		func(context.Context, []*Operation[int, any]) {},
+		WithMaxSize[int, any](4),
+		WithRegisterer[int, any](reg),
+		WithSplitter[int, any](func(ops []*Operation[int, any]) [][]*Operation[int, any] {
+			var evens, odds []*Operation[int, any]
+			for _, op := range ops {
+				if op.Value%2 == 0 {
+					evens = append(evens, op)
+				} else {
+					odds = append(odds, op)
+				}
+			}
+			return [][]*Operation[int, any]{evens, odds}
+		}),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go b.Start(ctx)
+
+	for _, v := range []int{1, 2, 3, 4} {
+		if _, err := b.Send(context.Background(), v); err != nil {
+			t.Fatalf("Send(%d): %v", v, err)
+		}
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	if got := histogramSampleCount(t, b.batchSize); got != 2 {
+		t.Errorf("batch_size observation count = %d, want 2 (one per sub-batch)", got)
+	}
+	if got := histogramSampleCount(t, b.commitDuration); got != 2 {
+		t.Errorf("commit_duration_seconds observation count = %d, want 2 (one per sub-batch)", got)
+	}
+}
+
+func TestBatcherWithConstLabels(t *testing.T) {
+	reg := prometheus.NewRegistry()
+
+	b := New[int, any](
+		func(context.Context, []*Operation[int, any]) {},
+		WithMaxSize[int, any](1),
+		WithRegisterer[int, any](reg),
+		WithConstLabels[int, any](prometheus.Labels{"instance": "a"}),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go b.Start(ctx)
+
+	if _, err := b.Send(context.Background(), 1); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	if got := testutil.ToFloat64(b.batchFlushReason.WithLabelValues("size")); got != 1 {
+		t.Errorf("batch_flush_reason_total{reason=size} = %v, want 1", got)
+	}
+}
@@ -0,0 +1,25 @@
+package batcher
+
+import "time"
+
+// WithMinSize sets the minimum number of operations a batch must contain
+// before Start will flush it on the timer set by [WithTimeout]. An
+// under-filled batch is still flushed once it has been pending for
+// [WithMaxLatency], so low-throughput producers keep their delivery
+// guarantees while high-throughput producers get denser batches. Using
+// WithMinSize requires both WithTimeout and WithMaxLatency to also be set.
+func WithMinSize[T, R any](n int) Option[T, R] {
+	return func(b *Batcher[T, R]) {
+		b.minSize = n
+	}
+}
+
+// WithMaxLatency sets the maximum duration a batch can be held back by
+// [WithMinSize] before Start flushes it regardless of its size. It has no
+// effect unless WithMinSize is also set. The default is [NoTimeout], which
+// panics if WithMinSize is used.
+func WithMaxLatency[T, R any](d time.Duration) Option[T, R] {
+	return func(b *Batcher[T, R]) {
+		b.maxLatency = d
+	}
+}
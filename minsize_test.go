@@ -0,0 +1,105 @@
+package batcher
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBatcherWithMinSizeWaitsForMaxLatency(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		batches   [][]*Operation[int, any]
+		flushedAt time.Time
+	)
+
+	b := New[int, any](
+		func(_ context.Context, ops []*Operation[int, any]) {
+			mu.Lock()
+			defer mu.Unlock()
+			batches = append(batches, ops)
+			flushedAt = time.Now()
+		},
+		WithMaxSize[int, any](10),
+		WithTimeout[int, any](10*time.Millisecond),
+		WithMinSize[int, any](5),
+		WithMaxLatency[int, any](60*time.Millisecond),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go b.Start(ctx)
+
+	start := time.Now()
+	if _, err := b.Send(context.Background(), 1); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(batches) != 1 {
+		t.Fatalf("got %d batches, want 1", len(batches))
+	}
+	if elapsed := flushedAt.Sub(start); elapsed < 60*time.Millisecond {
+		t.Errorf("batch flushed after %s, want at least max latency", elapsed)
+	}
+}
+
+func TestBatcherWithMinSizeReachedBeforeLatency(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		batches [][]*Operation[int, any]
+	)
+
+	b := New[int, any](
+		func(_ context.Context, ops []*Operation[int, any]) {
+			mu.Lock()
+			defer mu.Unlock()
+			batches = append(batches, ops)
+		},
+		WithMaxSize[int, any](10),
+		WithTimeout[int, any](10*time.Millisecond),
+		WithMinSize[int, any](2),
+		WithMaxLatency[int, any](1*time.Second),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go b.Start(ctx)
+
+	for i := 0; i < 2; i++ {
+		if _, err := b.Send(context.Background(), i); err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(batches) != 1 {
+		t.Fatalf("got %d batches, want 1", len(batches))
+	}
+	if len(batches[0]) != 2 {
+		t.Errorf("batch has %d operations, want 2", len(batches[0]))
+	}
+}
+
+func TestNewPanicsOnMinSizeWithoutMaxLatency(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic")
+		}
+	}()
+
+	New[int, any](
+		func(context.Context, []*Operation[int, any]) {},
+		WithTimeout[int, any](10*time.Millisecond),
+		WithMinSize[int, any](5),
+	)
+}
@@ -0,0 +1,44 @@
+package batcher
+
+import "context"
+
+// Operation represents a single value sent to a batcher, together with the
+// result produced once the batch it ends up in has been committed. Callers
+// obtain an *Operation from [Batcher.Send] and then call [Operation.Wait] to
+// block until the commit function has completed it.
+type Operation[T, R any] struct {
+	Value T
+
+	result R
+	err    error
+	done   chan struct{}
+}
+
+func newOperation[T, R any](v T) *Operation[T, R] {
+	return &Operation[T, R]{
+		Value: v,
+		done:  make(chan struct{}),
+	}
+}
+
+// Complete records the result of the operation and unblocks any goroutine
+// waiting on it via Wait. It is meant to be called by a CommitFunc once it
+// knows the outcome for this operation, and must be called exactly once.
+func (op *Operation[T, R]) Complete(result R, err error) {
+	op.result = result
+	op.err = err
+	close(op.done)
+}
+
+// Wait blocks until the operation has been completed, returning its result
+// and error. If the provided context expires first, Wait returns the
+// context's error instead.
+func (op *Operation[T, R]) Wait(ctx context.Context) (R, error) {
+	select {
+	case <-op.done:
+		return op.result, op.err
+	case <-ctx.Done():
+		var zero R
+		return zero, ctx.Err()
+	}
+}
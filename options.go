@@ -0,0 +1,67 @@
+package batcher
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"time"
+)
+
+// Option configures a Batcher. Options are applied in the order they are
+// passed to New.
+type Option[T, R any] func(*Batcher[T, R])
+
+// WithMaxSize sets the maximum number of operations in a batch. Start commits
+// a batch as soon as it reaches this size. The default is [UnlimitedSize].
+func WithMaxSize[T, R any](n int) Option[T, R] {
+	return func(b *Batcher[T, R]) {
+		b.maxSize = n
+	}
+}
+
+// WithTimeout sets the maximum duration Start waits after receiving the first
+// operation of a batch before committing it. The default is [NoTimeout].
+func WithTimeout[T, R any](d time.Duration) Option[T, R] {
+	return func(b *Batcher[T, R]) {
+		b.timeout = d
+	}
+}
+
+// WithRegisterer sets the Prometheus registerer used to register the
+// batcher's metrics. By default the metrics are not registered with any
+// registry.
+func WithRegisterer[T, R any](r prometheus.Registerer) Option[T, R] {
+	return func(b *Batcher[T, R]) {
+		b.registry = r
+	}
+}
+
+// WithNamespace sets the namespace component of the batcher's metric names.
+func WithNamespace[T, R any](ns string) Option[T, R] {
+	return func(b *Batcher[T, R]) {
+		b.namespace = ns
+	}
+}
+
+// WithSubsystem sets the subsystem component of the batcher's metric names.
+func WithSubsystem[T, R any](ss string) Option[T, R] {
+	return func(b *Batcher[T, R]) {
+		b.subsystem = ss
+	}
+}
+
+// WithConstLabels sets labels attached to every metric the batcher
+// registers, useful for distinguishing multiple batcher instances sharing a
+// registry. The default is no labels.
+func WithConstLabels[T, R any](labels prometheus.Labels) Option[T, R] {
+	return func(b *Batcher[T, R]) {
+		b.constLabels = labels
+	}
+}
+
+// WithHistogramBuckets sets the bucket boundaries used by all of the
+// batcher's histograms (batch_size, batch_bytes, batch_fill_duration_seconds
+// and commit_duration_seconds). The default is [prometheus.DefBuckets].
+func WithHistogramBuckets[T, R any](buckets []float64) Option[T, R] {
+	return func(b *Batcher[T, R]) {
+		b.histogramBuckets = buckets
+	}
+}
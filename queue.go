@@ -0,0 +1,99 @@
+package batcher
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// Queue is a pluggable backing store for operations pending commit, used by
+// [NewDurable]. Start dequeues values to build batches and only acks an
+// entry once the batch containing it has been committed successfully, so
+// entries that are enqueued but never acked are redelivered by Dequeue —
+// which lets a Queue implementation replay uncommitted work after a
+// restart.
+//
+// [NewMemQueue] is a non-durable in-memory implementation. [NewFileQueue]
+// is a durable, on-disk one.
+type Queue[T any] interface {
+	// Enqueue adds v to the queue, blocking until there is room or ctx
+	// expires.
+	Enqueue(ctx context.Context, v T) error
+
+	// Dequeue returns the next value in the queue along with an id to pass
+	// to Ack once it has been committed, blocking until one is available or
+	// ctx expires.
+	Dequeue(ctx context.Context) (v T, id uint64, err error)
+
+	// Ack marks the entry identified by id as committed, so it is not
+	// redelivered by Dequeue after a restart.
+	Ack(id uint64) error
+
+	// Len returns the number of entries currently enqueued but not yet
+	// dequeued.
+	Len() int
+}
+
+// queueEntry pairs a queued value with the id Ack expects. Its fields are
+// exported so it can be gob-encoded by [FileQueue].
+type queueEntry[T any] struct {
+	ID uint64
+	V  T
+}
+
+// memQueue is the non-durable, in-memory [Queue] returned by [NewMemQueue].
+type memQueue[T any] struct {
+	mu      sync.Mutex
+	nextID  uint64
+	ch      chan queueEntry[T]
+	pending int64
+}
+
+// NewMemQueue returns a non-durable, in-memory [Queue] that holds up to
+// capacity entries that have been enqueued but not yet dequeued. It does not
+// survive process restarts; use [NewFileQueue] for that.
+func NewMemQueue[T any](capacity int) Queue[T] {
+	return &memQueue[T]{ch: make(chan queueEntry[T], capacity)}
+}
+
+func (q *memQueue[T]) Enqueue(ctx context.Context, v T) error {
+	q.mu.Lock()
+	q.nextID++
+	id := q.nextID
+	q.mu.Unlock()
+
+	select {
+	case q.ch <- queueEntry[T]{ID: id, V: v}:
+		atomic.AddInt64(&q.pending, 1)
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (q *memQueue[T]) Dequeue(ctx context.Context) (T, uint64, error) {
+	select {
+	case e := <-q.ch:
+		atomic.AddInt64(&q.pending, -1)
+		return e.V, e.ID, nil
+	case <-ctx.Done():
+		var zero T
+		return zero, 0, ctx.Err()
+	}
+}
+
+func (q *memQueue[T]) Ack(uint64) error {
+	return nil
+}
+
+func (q *memQueue[T]) Len() int {
+	return int(atomic.LoadInt64(&q.pending))
+}
+
+// WithQueue sets the queue [NewDurable] consumes pending operations from.
+// NewDurable panics if it is not set.
+func WithQueue[T, R any](q Queue[T]) Option[T, R] {
+	return func(b *Batcher[T, R]) {
+		b.queue = q
+	}
+}
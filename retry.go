@@ -0,0 +1,65 @@
+package batcher
+
+import (
+	"context"
+	"time"
+)
+
+// RetryableCommitFunc is a [CommitFunc] variant that reports failure. It is
+// used with [NewDurable]: Start retries a batch, without acking its
+// entries, until it succeeds or the batcher's context expires, backing off
+// between attempts according to [WithRetryPolicy].
+type RetryableCommitFunc[T, R any] func(context.Context, []*Operation[T, R]) error
+
+// RetryPolicy controls the backoff Start applies between retries of a
+// failed commit when using [NewDurable]. Each field falls back to its
+// default when zero.
+type RetryPolicy struct {
+	// InitialBackoff is the delay before the first retry. The default is
+	// 100ms.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the delay between retries. The default is 30s.
+	MaxBackoff time.Duration
+
+	// Multiplier scales the delay after each failed attempt. The default is
+	// 2.
+	Multiplier float64
+}
+
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	initial := p.InitialBackoff
+	if initial <= 0 {
+		initial = 100 * time.Millisecond
+	}
+	maxBackoff := p.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = 30 * time.Second
+	}
+	mult := p.Multiplier
+	if mult <= 0 {
+		mult = 2
+	}
+
+	d := initial
+	if d >= maxBackoff {
+		return maxBackoff
+	}
+	for i := 0; i < attempt; i++ {
+		d = time.Duration(float64(d) * mult)
+		if d >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return d
+}
+
+// WithRetryPolicy sets the backoff policy Start uses between retries of a
+// failed commit when using [NewDurable]. It has no effect with [New]. The
+// default is [RetryPolicy]{}, which backs off from 100ms up to 30s, doubling
+// after each attempt.
+func WithRetryPolicy[T, R any](p RetryPolicy) Option[T, R] {
+	return func(b *Batcher[T, R]) {
+		b.retryPolicy = p
+	}
+}
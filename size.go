@@ -0,0 +1,66 @@
+package batcher
+
+import "errors"
+
+// UnlimitedBytes Batch an unlimited number of bytes.
+const UnlimitedBytes = 0
+
+// ErrOperationTooLarge is returned by Send when the operation's size, on its
+// own, exceeds the batcher's max byte size.
+var ErrOperationTooLarge = errors.New("batcher: operation exceeds max batch byte size")
+
+// Sizer is implemented by values that know their own size in bytes. When a
+// value sent through [Batcher.Send] implements Sizer, its Size method is used
+// to track the accumulated byte size of a batch, unless overridden by
+// [WithSizeFunc].
+type Sizer interface {
+	Size() int
+}
+
+// WithMaxBytes sets the maximum accumulated size, in bytes, of a batch. Start
+// commits a batch as soon as its accumulated size reaches this threshold. An
+// operation that would take a batch past the threshold is not added to it:
+// the batch is committed without it, and the operation starts the next
+// batch. That next batch is not checked against the threshold until another
+// operation arrives or its timeout occurs, even when the operation's own
+// size reaches the threshold. Each operation's size is determined by the function set with
+// [WithSizeFunc], or by T's Size method if it implements [Sizer]; values that
+// do none of this are treated as size 0. The default is [UnlimitedBytes].
+//
+// Send rejects, with [ErrOperationTooLarge], any value whose own size exceeds
+// n, since it could never fit in a batch on its own.
+func WithMaxBytes[T, R any](n int) Option[T, R] {
+	return func(b *Batcher[T, R]) {
+		b.maxBytes = n
+	}
+}
+
+// WithSizeFunc sets the function used to compute the size, in bytes, of each
+// operation sent to the batcher. It takes precedence over T implementing
+// [Sizer].
+func WithSizeFunc[T, R any](f func(T) int) Option[T, R] {
+	return func(b *Batcher[T, R]) {
+		b.sizeFunc = f
+	}
+}
+
+// sizeOf returns the size of v according to the batcher's size function, or
+// falls back to v's Size method if it implements Sizer, or 0 otherwise.
+func (b *Batcher[T, R]) sizeOf(v T) int {
+	if b.sizeFunc != nil {
+		return b.sizeFunc(v)
+	}
+	if s, ok := any(v).(Sizer); ok {
+		return s.Size()
+	}
+	return 0
+}
+
+// batchBytes returns the accumulated size of batch according to sizeOf.
+func (b *Batcher[T, R]) batchBytes(batch []*Operation[T, R]) int {
+	var total int
+	for _, op := range batch {
+		total += b.sizeOf(op.Value)
+	}
+	return total
+}
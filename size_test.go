@@ -0,0 +1,110 @@
+package batcher
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+type sizedValue int
+
+func (v sizedValue) Size() int {
+	return int(v)
+}
+
+func TestBatcherWithMaxBytes(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		batches [][]*Operation[sizedValue, any]
+	)
+
+	b := New[sizedValue, any](
+		func(_ context.Context, ops []*Operation[sizedValue, any]) {
+			mu.Lock()
+			defer mu.Unlock()
+			batches = append(batches, ops)
+		},
+		WithMaxBytes[sizedValue, any](10),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go b.Start(ctx)
+
+	for _, v := range []sizedValue{4, 4, 4} {
+		if _, err := b.Send(context.Background(), v); err != nil {
+			t.Fatalf("Send(%d): %v", v, err)
+		}
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	time.Sleep(50 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(batches) != 2 {
+		t.Fatalf("got %d batches, want 2", len(batches))
+	}
+	if len(batches[0]) != 2 {
+		t.Errorf("first batch has %d operations, want 2", len(batches[0]))
+	}
+	if len(batches[1]) != 1 {
+		t.Errorf("second batch has %d operations, want 1", len(batches[1]))
+	}
+}
+
+func TestBatcherWithMaxBytesAndMaxSize(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		batches [][]*Operation[sizedValue, any]
+	)
+
+	b := New[sizedValue, any](
+		func(_ context.Context, ops []*Operation[sizedValue, any]) {
+			mu.Lock()
+			defer mu.Unlock()
+			batches = append(batches, ops)
+		},
+		WithMaxSize[sizedValue, any](10),
+		WithMaxBytes[sizedValue, any](10),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go b.Start(ctx)
+	defer cancel()
+
+	for _, v := range []sizedValue{5, 5} {
+		if _, err := b.Send(context.Background(), v); err != nil {
+			t.Fatalf("Send(%d): %v", v, err)
+		}
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(batches) != 1 {
+		t.Fatalf("got %d batches, want 1", len(batches))
+	}
+	if len(batches[0]) != 2 {
+		t.Errorf("batch has %d operations, want 2", len(batches[0]))
+	}
+}
+
+func TestBatcherSendRejectsOversizeOperation(t *testing.T) {
+	b := New[sizedValue, any](
+		func(context.Context, []*Operation[sizedValue, any]) {},
+		WithMaxBytes[sizedValue, any](10),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go b.Start(ctx)
+
+	if _, err := b.Send(context.Background(), sizedValue(11)); err != ErrOperationTooLarge {
+		t.Fatalf("Send(11) error = %v, want ErrOperationTooLarge", err)
+	}
+}
@@ -0,0 +1,24 @@
+package batcher
+
+// WithSplitter sets a function Start applies to each completed batch before
+// committing it, calling the commit function (or a worker, if [WithWorkers]
+// is set) once per returned sub-batch instead of once for the whole batch.
+// This lets callers enforce a downstream constraint the batcher itself
+// doesn't know about, such as a stricter count or byte limit, or per-key
+// affinity (e.g. grouping operations bound for the same shard into the same
+// sub-batch). The splitter must account for the whole batch: operations it
+// drops are never committed.
+func WithSplitter[T, R any](f func([]*Operation[T, R]) [][]*Operation[T, R]) Option[T, R] {
+	return func(b *Batcher[T, R]) {
+		b.splitter = f
+	}
+}
+
+// splitBatch applies splitter to batch, or returns batch unsplit if splitter
+// is nil.
+func splitBatch[T, R any](splitter func([]*Operation[T, R]) [][]*Operation[T, R], batch []*Operation[T, R]) [][]*Operation[T, R] {
+	if splitter == nil {
+		return [][]*Operation[T, R]{batch}
+	}
+	return splitter(batch)
+}
@@ -0,0 +1,64 @@
+package batcher
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBatcherWithSplitter(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		batches [][]int
+	)
+
+	b := New[int, any](
+		func(_ context.Context, ops []*Operation[int, any]) {
+			mu.Lock()
+			defer mu.Unlock()
+			var vals []int
+			for _, op := range ops {
+				vals = append(vals, op.Value)
+			}
+			batches = append(batches, vals)
+		},
+		WithMaxSize[int, any](4),
+		WithSplitter[int, any](func(ops []*Operation[int, any]) [][]*Operation[int, any] {
+			var evens, odds []*Operation[int, any]
+			for _, op := range ops {
+				if op.Value%2 == 0 {
+					evens = append(evens, op)
+				} else {
+					odds = append(odds, op)
+				}
+			}
+			return [][]*Operation[int, any]{evens, odds}
+		}),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go b.Start(ctx)
+
+	for _, v := range []int{1, 2, 3, 4} {
+		if _, err := b.Send(context.Background(), v); err != nil {
+			t.Fatalf("Send(%d): %v", v, err)
+		}
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(batches) != 2 {
+		t.Fatalf("got %d sub-batches, want 2", len(batches))
+	}
+	if got := batches[0]; len(got) != 2 || got[0] != 2 || got[1] != 4 {
+		t.Errorf("first sub-batch = %v, want [2 4]", got)
+	}
+	if got := batches[1]; len(got) != 2 || got[0] != 1 || got[1] != 3 {
+		t.Errorf("second sub-batch = %v, want [1 3]", got)
+	}
+}
@@ -0,0 +1,12 @@
+package batcher
+
+// WithWorkers sets the number of goroutines Start uses to call the commit
+// function concurrently. Completed batches are handed off to these workers
+// over a channel buffered to n, so a slow commit blocks intake only once all
+// n workers are busy and n more batches are waiting. The default is 0, which
+// calls the commit function inline in Start's receive loop.
+func WithWorkers[T, R any](n int) Option[T, R] {
+	return func(b *Batcher[T, R]) {
+		b.workers = n
+	}
+}
@@ -0,0 +1,90 @@
+package batcher
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBatcherWithWorkersRunsCommitsConcurrently(t *testing.T) {
+	var inFlight, maxInFlight int64
+
+	b := New[int, any](
+		func(context.Context, []*Operation[int, any]) {
+			n := atomic.AddInt64(&inFlight, 1)
+			for {
+				m := atomic.LoadInt64(&maxInFlight)
+				if n <= m || atomic.CompareAndSwapInt64(&maxInFlight, m, n) {
+					break
+				}
+			}
+			time.Sleep(30 * time.Millisecond)
+			atomic.AddInt64(&inFlight, -1)
+		},
+		WithMaxSize[int, any](1),
+		WithWorkers[int, any](3),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go b.Start(ctx)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func(v int) {
+			defer wg.Done()
+			if _, err := b.Send(context.Background(), v); err != nil {
+				t.Errorf("Send: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	time.Sleep(50 * time.Millisecond)
+
+	if got := atomic.LoadInt64(&maxInFlight); got < 2 {
+		t.Errorf("max concurrent commits = %d, want at least 2", got)
+	}
+}
+
+func TestBatcherWithWorkersDrainsOnShutdown(t *testing.T) {
+	var committed int64
+
+	b := New[int, any](
+		func(context.Context, []*Operation[int, any]) {
+			time.Sleep(30 * time.Millisecond)
+			atomic.AddInt64(&committed, 1)
+		},
+		WithMaxSize[int, any](1),
+		WithWorkers[int, any](2),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		b.Start(ctx)
+		close(done)
+	}()
+
+	if _, err := b.Send(context.Background(), 1); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Start did not return")
+	}
+
+	if atomic.LoadInt64(&committed) != 1 {
+		t.Errorf("committed = %d, want 1", atomic.LoadInt64(&committed))
+	}
+}